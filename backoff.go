@@ -0,0 +1,44 @@
+package glink
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBackoffBase       = 100 * time.Millisecond
+	defaultBackoffCap        = 30 * time.Second
+	defaultBackoffMultiplier = 1.6
+)
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// jitterFloat64 returns a random float64 in [0,1) from a package-local,
+// mutex-guarded rand.Rand, so backoffDuration doesn't depend on (or block on)
+// the global math/rand source.
+func jitterFloat64() float64 {
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+
+	return jitterRand.Float64()
+}
+
+// backoffDuration implements the "full jitter" strategy used by gRPC's own
+// internal/backoff package: given the configured base, cap and multiplier,
+// it returns a random duration in [0, min(cap, base*multiplier^attempt)].
+// This replaces a plain 2^attempt backoff, which produces synchronized
+// retry storms when many clients fail at once and can blow past any sane
+// bound within a handful of attempts.
+func (cm *ConnectionManager) backoffDuration(attempt int) time.Duration {
+	upper := float64(cm.backoffBase) * math.Pow(cm.backoffMultiplier, float64(attempt))
+	if cap := float64(cm.backoffCap); upper > cap {
+		upper = cap
+	}
+
+	return time.Duration(jitterFloat64() * upper)
+}
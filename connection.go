@@ -35,7 +35,9 @@ func (c *Connection) Close() {
 	defer c.mu.Unlock()
 
 	GetLogger().Println("gRPC close connection Id:", c.id)
-	c.connection.Close()
+	if c.connection != nil {
+		c.connection.Close()
+	}
 	c.connection = nil
 }
 
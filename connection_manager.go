@@ -1,9 +1,13 @@
 package glink
 
 import (
+	"context"
+	"crypto/tls"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"math"
+	"google.golang.org/grpc/keepalive"
 	"sync"
 	"time"
 )
@@ -18,12 +22,61 @@ type ConnectionManager struct {
 	connection1      *Connection
 	maxConnectionAge time.Duration
 	maxRetries       int
-	serviceAddress   string
 	dialOpts         []grpc.DialOption
+
+	// endpoints is the list of service addresses the manager cycles
+	// through on reconnect; endpointIndex tracks the current one and is
+	// advanced by advanceEndpoint under mu.
+	endpoints         []string
+	endpointIndex     int
+	endpointSelection EndpointSelection
+
+	// tlsConfig and forceInsecure are populated by Option functions and
+	// consumed once, in NewWithOptions, to pick the transport credentials.
+	tlsConfig     *tls.Config
+	forceInsecure bool
+
+	// certErr is set by WithClientCertificate if the configured
+	// certificate/key pair fails to load; NewWithOptions panics on it
+	// rather than silently falling back to insecure credentials.
+	certErr error
+
+	// keepaliveParams and connectParams are populated by Option functions
+	// and, when set, consumed once in NewWithOptions to add the matching
+	// dial options.
+	keepaliveParams *keepalive.ClientParameters
+	connectParams   *grpc.ConnectParams
+
+	// backoffBase, backoffCap and backoffMultiplier parameterize
+	// backoffDuration's full-jitter retry delay; WithBackoff overrides the
+	// defaults set in NewWithOptions.
+	backoffBase       time.Duration
+	backoffCap        time.Duration
+	backoffMultiplier float64
+
+	// onStateChange, when set via WithOnStateChange, is invoked by the state
+	// monitor goroutine on every connectivity state transition.
+	onStateChange func(old, new connectivity.State)
+
+	// stateMonitorCancel stops the state monitor goroutine started in
+	// NewWithOptions; Close calls it.
+	stateMonitorCancel context.CancelFunc
+
+	// userUnaryInterceptors and userStreamInterceptors are set via
+	// WithUnaryInterceptors/WithStreamInterceptors and placed ahead of
+	// (outside) the retry interceptors in the dial chain.
+	userUnaryInterceptors  []grpc.UnaryClientInterceptor
+	userStreamInterceptors []grpc.StreamClientInterceptor
+
+	// perRPCCreds is installed via WithPerRPCCredentials, e.g. to attach a
+	// bearer/JWT token to every call.
+	perRPCCreds credentials.PerRPCCredentials
 }
 
 // New creates a new ConnectionManager instance for the specified service address
 // and establishes the necessary configuration for managing gRPC connections.
+// serviceAddress becomes the sole entry of the endpoint list; use WithEndpoints
+// or SetEndpoints to add failover targets.
 //
 // Parameters:
 //   - serviceAddress (string): The address of the service that the connection manager
@@ -35,6 +88,14 @@ type ConnectionManager struct {
 //   - logger (bool): A flag that determines whether logging is enabled for this connection manager.
 //     If set to true, logging will be enabled (e.g., to track connection status, retries, etc.).
 func New(serviceAddress string, maxConnectionAge time.Duration, maxRetries uint, logger bool) *ConnectionManager {
+	return NewWithOptions(serviceAddress, maxConnectionAge, maxRetries, logger)
+}
+
+// NewWithOptions creates a new ConnectionManager instance like New, additionally
+// applying the supplied Option values (e.g. WithTLS, WithClientCertificate).
+// The resulting transport credentials are built once, here, stored in
+// cm.dialOpts, and reused by connect() on every reconnect.
+func NewWithOptions(serviceAddress string, maxConnectionAge time.Duration, maxRetries uint, logger bool, opts ...Option) *ConnectionManager {
 
 	// Enable log
 	if logger == true {
@@ -47,19 +108,53 @@ func New(serviceAddress string, maxConnectionAge time.Duration, maxRetries uint,
 	}
 
 	cm := &ConnectionManager{
-		serviceAddress:   serviceAddress,
-		maxConnectionAge: maxConnectionAge,
-		maxRetries:       int(maxRetries),
-		connection1:      NewConnection(serviceAddress),
+		endpoints:         []string{serviceAddress},
+		maxConnectionAge:  maxConnectionAge,
+		maxRetries:        int(maxRetries),
+		connection1:       NewConnection(serviceAddress),
+		backoffBase:       defaultBackoffBase,
+		backoffCap:        defaultBackoffCap,
+		backoffMultiplier: defaultBackoffMultiplier,
+	}
+
+	for _, opt := range opts {
+		opt(cm)
+	}
+
+	if cm.certErr != nil {
+		panic(cm.certErr)
+	}
+
+	var transportCreds credentials.TransportCredentials
+	if !cm.forceInsecure && cm.tlsConfig != nil {
+		transportCreds = credentials.NewTLS(cm.tlsConfig)
+	} else {
+		transportCreds = insecure.NewCredentials()
 	}
 
 	dialOptions := []grpc.DialOption{}
-	dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(transportCreds))
 	dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`))
-	dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(retryInterceptor(cm)))
+
+	if cm.keepaliveParams != nil {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*cm.keepaliveParams))
+	}
+	if cm.connectParams != nil {
+		dialOptions = append(dialOptions, grpc.WithConnectParams(*cm.connectParams))
+	}
+	if cm.perRPCCreds != nil {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(cm.perRPCCreds))
+	}
+
+	dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(unaryInterceptorChain(cm)...))
+	dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(streamInterceptorChain(cm)...))
 
 	cm.dialOpts = dialOptions
 
+	stateCtx, cancel := context.WithCancel(context.Background())
+	cm.stateMonitorCancel = cancel
+	go cm.monitorConnectionState(stateCtx)
+
 	return cm
 }
 
@@ -69,7 +164,7 @@ func (cm *ConnectionManager) connect() error {
 	defer cm.mu.Unlock()
 
 	conn, grpcErr := grpc.NewClient(
-		cm.serviceAddress,
+		cm.currentEndpoint(),
 		cm.dialOpts...,
 	)
 
@@ -83,6 +178,8 @@ func (cm *ConnectionManager) connect() error {
 }
 
 func (cm *ConnectionManager) Close() {
+	cm.stateMonitorCancel()
+
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -101,10 +198,35 @@ func (cm *ConnectionManager) GetConnection() (*grpc.ClientConn, error) {
 	return cm.connection1.Connection(), err
 }
 
+// TryReconnect reconnects if the current connection has lived at least
+// minConnectionAge, which keeps unconditional reconnect callers from tearing
+// down a connection that another in-flight RPC might still be using
+// successfully.
 func (cm *ConnectionManager) TryReconnect() (*grpc.ClientConn, error) {
+	return cm.reconnect(false)
+}
+
+// forceReconnect reconnects immediately, bypassing the minConnectionAge gate.
+// It's for callers who already have positive evidence the current connection
+// is unusable (the state monitor's TransientFailure/Shutdown observation),
+// where waiting out the grace period would just leave a known-dead
+// connection in place and, for multi-endpoint setups, stall failover to the
+// next endpoint. RPC retries get the same treatment for Unavailable errors,
+// but through reconnect(isConnectionFailureStatus(err)) directly rather than
+// this wrapper.
+func (cm *ConnectionManager) forceReconnect() (*grpc.ClientConn, error) {
+	return cm.reconnect(true)
+}
+
+func (cm *ConnectionManager) reconnect(force bool) (*grpc.ClientConn, error) {
 	var err error
 
-	if time.Now().Sub(cm.connection1.lastConnection) >= minConnectionAge {
+	if force || time.Now().Sub(cm.connection1.lastConnection) >= minConnectionAge {
+		// Move off the endpoint that just failed before redialing.
+		cm.mu.Lock()
+		cm.advanceEndpoint()
+		cm.mu.Unlock()
+
 		err = cm.connect()
 		if err != nil {
 			return cm.connection1.Connection(), err
@@ -117,11 +239,3 @@ func (cm *ConnectionManager) TryReconnect() (*grpc.ClientConn, error) {
 func (cm *ConnectionManager) ShouldReconnect() bool {
 	return cm.connection1.IsExpired()
 }
-
-func (cm *ConnectionManager) backoffDuration(attempt int) time.Duration {
-	var baseRetryDelay = 100 * time.Millisecond
-
-	delay := float64(baseRetryDelay) * math.Pow(2, float64(attempt))
-
-	return time.Duration(delay)
-}
@@ -2,29 +2,34 @@ package glink
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 )
 
 const bufSize = 1024 * 1024
 
-// newBufConn returns a dialer option using a bufconn listener.
+// newBufConn returns a dialer option using a bufconn listener. Serve's error
+// on the listener being closed is expected once a test is done with it, so
+// (like newMultiBufConn/newBufConnService) it is discarded rather than
+// fatal: log.Fatalf here would call os.Exit and take down the whole test
+// binary, not just this test, the moment any test closes its listener.
 func newBufConn() (grpc.DialOption, *bufconn.Listener) {
 	lis := bufconn.Listen(bufSize)
-	// Create a dummy gRPC server.
 	s := grpc.NewServer()
-	go func() {
-		if err := s.Serve(lis); err != nil {
-			log.Fatalf("Server exited with error: %v", err)
-		}
-	}()
+	go func() { _ = s.Serve(lis) }()
 	dialerOpt := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
 		return lis.Dial()
 	})
@@ -130,6 +135,7 @@ func TestConnectionManager_ShouldReconnect(t *testing.T) {
 
 	// Create a ConnectionManager instance
 	cm := New("bufnet", 5*time.Second, 3, true)
+	defer cm.Close()
 	cm.dialOpts = append(cm.dialOpts, dialOpt)
 
 	// Simulate the connection being expired
@@ -141,6 +147,575 @@ func TestConnectionManager_ShouldReconnect(t *testing.T) {
 	}
 }
 
+// newBufConnService is like newBufConn but also returns the *grpc.Server,
+// so a test can call Stop() to force-close already-accepted connections
+// (closing the listener alone only stops accepting new ones).
+func newBufConnService() (grpc.DialOption, *grpc.Server, *bufconn.Listener) {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	dialerOpt := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.Dial()
+	})
+	return dialerOpt, s, lis
+}
+
+// TestConnectionManager_StateMonitorReconnect verifies that the background
+// state monitor triggers a reconnect as soon as it observes the connection
+// enter TransientFailure, without any RPC being issued. A dropped transport
+// takes the connection Ready -> Idle first, not straight to
+// TransientFailure, so this also exercises monitorConnectionState's
+// Connect() nudge out of Idle and forceReconnect's bypass of
+// minConnectionAge (the connection here is brand new, well under it).
+func TestConnectionManager_StateMonitorReconnect(t *testing.T) {
+	dialOpt, server, lis := newBufConnService()
+	defer lis.Close()
+
+	transientFailureSeen := make(chan struct{}, 1)
+	onStateChange := func(old, new connectivity.State) {
+		if new == connectivity.TransientFailure {
+			select {
+			case transientFailureSeen <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	// "passthrough:///" ensures conn.Connect() below actually dials through
+	// our custom bufconn dialer instead of going through the default
+	// resolver, which would otherwise treat "bufnet" as a DNS name.
+	cm := NewWithOptions("passthrough:///bufnet", 5*time.Second, 3, true, WithOnStateChange(onStateChange))
+	cm.dialOpts = append(cm.dialOpts, dialOpt)
+	defer cm.Close()
+
+	origConn, err := cm.GetConnection()
+	if err != nil {
+		t.Fatalf("Failed to get connection from ConnectionManager: %v", err)
+	}
+
+	// Force the lazy connection to actually dial; this is a connectivity
+	// control call, not an RPC.
+	origConn.Connect()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for origConn.GetState() != connectivity.Ready && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if state := origConn.GetState(); state != connectivity.Ready {
+		t.Fatalf("Expected connection to reach Ready, got: %v", state)
+	}
+
+	// Simulate the server going away entirely.
+	server.Stop()
+
+	select {
+	case <-transientFailureSeen:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for state monitor to observe TransientFailure")
+	}
+
+	deadline = time.Now().Add(2 * time.Second)
+	for cm.connection1.Connection() == origConn && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cm.connection1.Connection() == origConn {
+		t.Fatalf("Expected state monitor to trigger a reconnect after TransientFailure")
+	}
+}
+
+// TestConnectionManager_BackoffDuration verifies that backoffDuration stays
+// within [0, cap] for a range of attempts, including ones where
+// base*multiplier^attempt would otherwise overshoot the cap.
+func TestConnectionManager_BackoffDuration(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 3, false)
+	defer cm.Close()
+	cm.backoffBase = 100 * time.Millisecond
+	cm.backoffCap = 2 * time.Second
+	cm.backoffMultiplier = 1.6
+
+	for attempt := 0; attempt < 20; attempt++ {
+		for i := 0; i < 10; i++ {
+			d := cm.backoffDuration(attempt)
+			if d < 0 || d > cm.backoffCap {
+				t.Fatalf("attempt %d: backoffDuration returned %v, want within [0, %v]", attempt, d, cm.backoffCap)
+			}
+		}
+	}
+}
+
+// newMultiBufConn returns a dial option that routes to a distinct bufconn
+// listener per address, keyed by the address passed to grpc.NewClient.
+func newMultiBufConn(addrs []string) (grpc.DialOption, map[string]*bufconn.Listener) {
+	listeners := make(map[string]*bufconn.Listener, len(addrs))
+	for _, addr := range addrs {
+		lis := bufconn.Listen(bufSize)
+		s := grpc.NewServer()
+		// As in newBufConn, the test closes one of these listeners on
+		// purpose to simulate a failed endpoint, so a Serve error here is
+		// expected and must not be fatal.
+		go func() { _ = s.Serve(lis) }()
+		listeners[addr] = lis
+	}
+
+	dialerOpt := grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		lis, ok := listeners[addr]
+		if !ok {
+			return nil, fmt.Errorf("no bufconn listener for address %q", addr)
+		}
+		return lis.Dial()
+	})
+
+	return dialerOpt, listeners
+}
+
+// TestConnectionManager_EndpointFailover verifies that once the primary
+// endpoint is due for a reconnect, ConnectionManager cycles to the next
+// endpoint in the list instead of redialing the same address.
+func TestConnectionManager_EndpointFailover(t *testing.T) {
+	addrs := []string{"endpoint-a", "endpoint-b"}
+	dialOpt, listeners := newMultiBufConn(addrs)
+	defer func() {
+		for _, lis := range listeners {
+			lis.Close()
+		}
+	}()
+
+	cm := New(addrs[0], 5*time.Second, 3, true)
+	cm.dialOpts = append(cm.dialOpts, dialOpt)
+	cm.SetEndpoints(addrs)
+
+	conn1, err := cm.GetConnection()
+	if err != nil {
+		t.Fatalf("Failed to get connection from ConnectionManager: %v", err)
+	}
+	if state := conn1.GetState(); state != connectivity.Idle && state != connectivity.Ready {
+		t.Fatalf("Expected connection to be Idle or Ready, got: %v", state)
+	}
+
+	// Simulate the primary endpoint failing and becoming eligible for retry.
+	listeners[addrs[0]].Close()
+	cm.connection1.lastConnection = time.Now().Add(-(minConnectionAge + time.Second))
+
+	conn2, err := cm.TryReconnect()
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	if conn2 == conn1 {
+		t.Fatalf("Expected a new connection after failover")
+	}
+	if got := cm.currentEndpoint(); got != addrs[1] {
+		t.Fatalf("Expected failover to endpoint %q, got %q", addrs[1], got)
+	}
+
+	cm.Close()
+}
+
+// TestRetryInterceptor_FailoverOnFreshConnection verifies that a retryable
+// RPC error advances to the next endpoint even when the current connection
+// is only seconds old, i.e. retryInterceptor's reconnect bypasses
+// minConnectionAge instead of stalling failover behind it until the
+// connection happens to age out.
+func TestRetryInterceptor_FailoverOnFreshConnection(t *testing.T) {
+	addrs := []string{"endpoint-a", "endpoint-b"}
+	dialOpt, listeners := newMultiBufConn(addrs)
+	defer func() {
+		for _, lis := range listeners {
+			lis.Close()
+		}
+	}()
+
+	cm := New(addrs[0], 5*time.Second, 3, false)
+	defer cm.Close()
+	cm.dialOpts = append(cm.dialOpts, dialOpt)
+	cm.SetEndpoints(addrs)
+
+	initialConn, err := cm.GetConnection()
+	if err != nil {
+		t.Fatalf("Failed to get initial connection: %v", err)
+	}
+
+	// Simulate the primary endpoint failing; cm.connection1.lastConnection is
+	// left untouched, seconds old, well under minConnectionAge.
+	listeners[addrs[0]].Close()
+
+	interceptor := retryInterceptor(cm)
+
+	var gotConns []*grpc.ClientConn
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		gotConns = append(gotConns, cc)
+		if len(gotConns) == 1 {
+			return status.Error(codes.Unavailable, "simulated failure")
+		}
+		return nil
+	}
+
+	if err := interceptor(context.Background(), "/test/Method", nil, nil, initialConn, invoker); err != nil {
+		t.Fatalf("Expected retry to succeed, got error: %v", err)
+	}
+
+	if got := cm.currentEndpoint(); got != addrs[1] {
+		t.Fatalf("Expected failover to endpoint %q on a fresh connection, got %q", addrs[1], got)
+	}
+	if len(gotConns) != 2 || gotConns[1] == initialConn {
+		t.Fatalf("Expected second attempt to use the failed-over connection")
+	}
+}
+
+// TestRetryInterceptor_UsesReconnectedConnection verifies that once a retry
+// triggers a reconnect, the next attempt is actually invoked on the
+// connection it returned, instead of the stale one the RPC was originally
+// dispatched on.
+func TestRetryInterceptor_UsesReconnectedConnection(t *testing.T) {
+	dialOpt, lis := newBufConn()
+	defer lis.Close()
+
+	cm := New("bufnet", 5*time.Second, 3, false)
+	defer cm.Close()
+	cm.dialOpts = append(cm.dialOpts, dialOpt)
+
+	initialConn, err := cm.GetConnection()
+	if err != nil {
+		t.Fatalf("Failed to get initial connection: %v", err)
+	}
+
+	interceptor := retryInterceptor(cm)
+
+	var gotConns []*grpc.ClientConn
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		gotConns = append(gotConns, cc)
+		if len(gotConns) == 1 {
+			return status.Error(codes.Unavailable, "simulated failure")
+		}
+		return nil
+	}
+
+	if err := interceptor(context.Background(), "/test/Method", nil, nil, initialConn, invoker); err != nil {
+		t.Fatalf("Expected retry to succeed, got error: %v", err)
+	}
+
+	if len(gotConns) != 2 {
+		t.Fatalf("Expected invoker to be called twice, got %d", len(gotConns))
+	}
+	if gotConns[0] != initialConn {
+		t.Fatalf("Expected first attempt to use the initial connection")
+	}
+	if gotConns[1] == initialConn {
+		t.Fatalf("Expected second attempt to use the reconnected connection, not the stale one")
+	}
+}
+
+// TestRetryInterceptor_NonRetryableErrorFailsFast verifies that a
+// non-retryable error (e.g. InvalidArgument) is returned after exactly one
+// invoker call, not retried up to maxRetries times.
+func TestRetryInterceptor_NonRetryableErrorFailsFast(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 5, false)
+	defer cm.Close()
+	interceptor := retryInterceptor(cm)
+
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.InvalidArgument, "bad request")
+	}
+
+	err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument to be returned, got: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected exactly 1 invoker call for a non-retryable error, got %d", calls)
+	}
+}
+
+// TestRetryInterceptor_ZeroMaxRetriesStillInvokes verifies that
+// New(addr, age, 0, false) still makes the call instead of silently
+// "succeeding" without ever invoking it.
+func TestRetryInterceptor_ZeroMaxRetriesStillInvokes(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 0, false)
+	defer cm.Close()
+	interceptor := retryInterceptor(cm)
+
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return nil
+	}
+
+	if err := interceptor(context.Background(), "/test/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected exactly 1 invoker call with maxRetries=0, got %d", calls)
+	}
+}
+
+// fakeClientStream is a minimal grpc.ClientStream for exercising
+// retryClientStream without a real connection.
+type fakeClientStream struct {
+	ctx context.Context
+}
+
+func (f *fakeClientStream) Header() (metadata.MD, error) { return nil, nil }
+func (f *fakeClientStream) Trailer() metadata.MD         { return nil }
+func (f *fakeClientStream) CloseSend() error             { return nil }
+func (f *fakeClientStream) Context() context.Context     { return f.ctx }
+func (f *fakeClientStream) SendMsg(m interface{}) error  { return nil }
+func (f *fakeClientStream) RecvMsg(m interface{}) error  { return nil }
+
+// TestNewStreamWithRetry_ZeroMaxRetriesStillCallsStreamer verifies that
+// newStreamWithRetry still calls streamer at least once when cm.maxRetries
+// is 0, instead of returning (nil, nil), which would hand back a
+// grpc.ClientStream that panics on first use.
+func TestNewStreamWithRetry_ZeroMaxRetriesStillCallsStreamer(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 0, false)
+	defer cm.Close()
+
+	calls := 0
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls++
+		return &fakeClientStream{ctx: ctx}, nil
+	}
+
+	stream, err := newStreamWithRetry(context.Background(), cm, &grpc.StreamDesc{}, nil, "/test/Method", streamer)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if stream == nil {
+		t.Fatalf("Expected a non-nil stream with maxRetries=0")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected exactly 1 streamer call with maxRetries=0, got %d", calls)
+	}
+}
+
+// TestRetryClientStream_ConcurrentSendRecv exercises SendMsg/RecvMsg from two
+// goroutines concurrently, as a bidi-streaming caller would. It only catches
+// anything under "go test -race": currentStream must read s.ClientStream
+// under mu since retryOnce can swap it out from either goroutine.
+func TestRetryClientStream_ConcurrentSendRecv(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 3, false)
+	defer cm.Close()
+
+	s := &retryClientStream{
+		ClientStream: &fakeClientStream{ctx: context.Background()},
+		cm:           cm,
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = s.SendMsg(i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			var out int
+			_ = s.RecvMsg(&out)
+		}
+	}()
+
+	wg.Wait()
+}
+
+// chainUnaryInvoker composes interceptors the way grpc-go's internal
+// chaining does: the first element is outermost, and each interceptor's
+// invoker re-enters the rest of the chain.
+func chainUnaryInvoker(interceptors []grpc.UnaryClientInterceptor, finalInvoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	if len(interceptors) == 0 {
+		return finalInvoker
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return interceptors[0](ctx, method, req, reply, cc, chainUnaryInvoker(interceptors[1:], finalInvoker), opts...)
+	}
+}
+
+// TestInterceptorChain_RetryIsInnermost verifies that retryInterceptor is
+// placed innermost in unaryInterceptorChain, so a user interceptor added via
+// WithUnaryInterceptors sees exactly one call per RPC, for the attempt the
+// retries settle on, instead of once per retry attempt.
+func TestInterceptorChain_RetryIsInnermost(t *testing.T) {
+	cm := New("bufnet", 5*time.Second, 3, false)
+	defer cm.Close()
+
+	userCalls := 0
+	userInterceptor := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		userCalls++
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	cm.userUnaryInterceptors = append(cm.userUnaryInterceptors, userInterceptor)
+
+	attempts := 0
+	finalInvoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+		if attempts < 3 {
+			return status.Error(codes.Unavailable, "simulated failure")
+		}
+		return nil
+	}
+
+	invoke := chainUnaryInvoker(unaryInterceptorChain(cm), finalInvoker)
+	if err := invoke(context.Background(), "/test/Method", nil, nil, nil); err != nil {
+		t.Fatalf("Expected eventual success, got: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("Expected 3 raw attempts, got %d", attempts)
+	}
+	if userCalls != 1 {
+		t.Fatalf("Expected user interceptor to observe exactly 1 call (the settled result), got %d", userCalls)
+	}
+}
+
+// fakePerRPCCredentials is a minimal credentials.PerRPCCredentials for
+// TestWithPerRPCCredentials.
+type fakePerRPCCredentials struct{}
+
+func (fakePerRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer test"}, nil
+}
+func (fakePerRPCCredentials) RequireTransportSecurity() bool { return false }
+
+// TestWithPerRPCCredentials verifies the option sets cm.perRPCCreds.
+func TestWithPerRPCCredentials(t *testing.T) {
+	cm := &ConnectionManager{}
+	creds := fakePerRPCCredentials{}
+
+	WithPerRPCCredentials(creds)(cm)
+
+	if cm.perRPCCreds != creds {
+		t.Fatalf("Expected perRPCCreds to be set to the supplied credentials")
+	}
+}
+
+// TestWithUnaryInterceptors verifies the option appends to, rather than
+// replaces, any previously configured interceptors.
+func TestWithUnaryInterceptors(t *testing.T) {
+	cm := &ConnectionManager{}
+	noop := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	WithUnaryInterceptors(noop)(cm)
+	WithUnaryInterceptors(noop, noop)(cm)
+
+	if len(cm.userUnaryInterceptors) != 3 {
+		t.Fatalf("Expected 3 accumulated interceptors, got %d", len(cm.userUnaryInterceptors))
+	}
+}
+
+// TestNewWithOptions_PanicsOnBadClientCertificate verifies that a
+// WithClientCertificate load failure prevents construction instead of
+// silently falling back to insecure credentials.
+func TestNewWithOptions_PanicsOnBadClientCertificate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected NewWithOptions to panic on an unloadable client certificate")
+		}
+	}()
+
+	NewWithOptions("bufnet", 5*time.Second, 3, false,
+		WithClientCertificate("/nonexistent/cert.pem", "/nonexistent/key.pem", nil))
+}
+
+// TestWithTLS verifies the option sets cm.tlsConfig to the supplied config.
+func TestWithTLS(t *testing.T) {
+	cm := &ConnectionManager{}
+	cfg := &tls.Config{ServerName: "example.com"}
+
+	WithTLS(cfg)(cm)
+
+	if cm.tlsConfig != cfg {
+		t.Fatalf("Expected tlsConfig to be set to the supplied config")
+	}
+}
+
+// TestWithServerName verifies the option sets ServerName on a lazily
+// created tls.Config when no earlier option supplied one.
+func TestWithServerName(t *testing.T) {
+	cm := &ConnectionManager{}
+
+	WithServerName("example.com")(cm)
+
+	if cm.tlsConfig == nil || cm.tlsConfig.ServerName != "example.com" {
+		t.Fatalf("Expected tlsConfig.ServerName to be set to %q, got %+v", "example.com", cm.tlsConfig)
+	}
+}
+
+// TestWithKeepalive verifies the option sets cm.keepaliveParams.
+func TestWithKeepalive(t *testing.T) {
+	cm := &ConnectionManager{}
+	params := keepalive.ClientParameters{Time: 10 * time.Second, Timeout: 2 * time.Second}
+
+	WithKeepalive(params)(cm)
+
+	if cm.keepaliveParams == nil || *cm.keepaliveParams != params {
+		t.Fatalf("Expected keepaliveParams to be set to %+v, got %+v", params, cm.keepaliveParams)
+	}
+}
+
+// TestWithConnectParams verifies the option sets cm.connectParams.
+func TestWithConnectParams(t *testing.T) {
+	cm := &ConnectionManager{}
+	params := grpc.ConnectParams{MinConnectTimeout: 5 * time.Second}
+
+	WithConnectParams(params)(cm)
+
+	if cm.connectParams == nil || *cm.connectParams != params {
+		t.Fatalf("Expected connectParams to be set to %+v, got %+v", params, cm.connectParams)
+	}
+}
+
+// TestWithEndpoints verifies the option replaces cm.endpoints and resets
+// the selection index, mirroring SetEndpoints.
+func TestWithEndpoints(t *testing.T) {
+	cm := &ConnectionManager{endpointIndex: 1}
+
+	WithEndpoints("a", "b")(cm)
+
+	if len(cm.endpoints) != 2 || cm.endpoints[0] != "a" || cm.endpoints[1] != "b" {
+		t.Fatalf("Expected endpoints to be [a b], got %v", cm.endpoints)
+	}
+	if cm.endpointIndex != 0 {
+		t.Fatalf("Expected endpointIndex to be reset to 0, got %d", cm.endpointIndex)
+	}
+}
+
+// TestWithInsecure_OverridesEarlierTLS verifies that WithInsecure applied
+// after WithTLS clears tlsConfig and forces forceInsecure, which
+// NewWithOptions uses to pick insecure.NewCredentials() over TLS.
+func TestWithInsecure_OverridesEarlierTLS(t *testing.T) {
+	cm := &ConnectionManager{}
+
+	WithTLS(&tls.Config{ServerName: "example.com"})(cm)
+	WithInsecure()(cm)
+
+	if cm.tlsConfig != nil {
+		t.Fatalf("Expected tlsConfig to be cleared, got %+v", cm.tlsConfig)
+	}
+	if !cm.forceInsecure {
+		t.Fatalf("Expected forceInsecure to be true")
+	}
+
+	// Applied through NewWithOptions in the same order, this must still
+	// result in insecure credentials rather than the earlier TLS config.
+	cm2 := NewWithOptions("bufnet", 5*time.Second, 3, false,
+		WithTLS(&tls.Config{ServerName: "example.com"}),
+		WithInsecure(),
+	)
+	defer cm2.Close()
+	if cm2.tlsConfig != nil || !cm2.forceInsecure {
+		t.Fatalf("Expected WithInsecure to win over an earlier WithTLS")
+	}
+}
+
 // TestConnectionManager_TryReconnect verifies that TryReconnect attempts a reconnection when needed.
 func TestConnectionManager_TryReconnect(t *testing.T) {
 	// Prepare bufconn dial option.
@@ -0,0 +1,58 @@
+package glink
+
+import (
+	"math/rand"
+)
+
+// EndpointSelection controls how ConnectionManager picks the next endpoint
+// to dial when a reconnect is triggered.
+type EndpointSelection int
+
+const (
+	// RoundRobin cycles through the endpoint list in order. This is the default.
+	RoundRobin EndpointSelection = iota
+	// RandomEndpoint picks a pseudo-random endpoint on every reconnect.
+	RandomEndpoint
+	// StickyEndpoint keeps dialing the current endpoint; it only moves on
+	// when SetEndpoints is called.
+	StickyEndpoint
+)
+
+// SetEndpoints replaces the list of service addresses the ConnectionManager
+// cycles through and resets the selection to the first endpoint. It is safe
+// to call while the ConnectionManager is in use; the new list takes effect
+// on the next reconnect.
+func (cm *ConnectionManager) SetEndpoints(endpoints []string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.endpoints = endpoints
+	cm.endpointIndex = 0
+}
+
+// currentEndpoint returns the endpoint that connect() should dial next.
+// Callers must hold cm.mu.
+func (cm *ConnectionManager) currentEndpoint() string {
+	if len(cm.endpoints) == 0 {
+		return ""
+	}
+
+	return cm.endpoints[cm.endpointIndex%len(cm.endpoints)]
+}
+
+// advanceEndpoint selects the next endpoint to dial according to the
+// configured selection strategy. Callers must hold cm.mu.
+func (cm *ConnectionManager) advanceEndpoint() {
+	if len(cm.endpoints) <= 1 {
+		return
+	}
+
+	switch cm.endpointSelection {
+	case RandomEndpoint:
+		cm.endpointIndex = rand.Intn(len(cm.endpoints))
+	case StickyEndpoint:
+		// Stay on the current endpoint until SetEndpoints moves us.
+	default: // RoundRobin
+		cm.endpointIndex = (cm.endpointIndex + 1) % len(cm.endpoints)
+	}
+}
@@ -5,27 +5,67 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"sync"
 	"time"
 )
 
+// unaryInterceptorChain builds the dial chain passed to
+// grpc.WithChainUnaryInterceptor. grpc-go chains its first element
+// outermost (called first, re-invoking the rest of the chain on every
+// retry attempt), so retryInterceptor is placed last/innermost: user
+// interceptors added via WithUnaryInterceptors then see exactly one call,
+// for the attempt the retries settle on, rather than once per attempt.
+func unaryInterceptorChain(cm *ConnectionManager) []grpc.UnaryClientInterceptor {
+	return append(append([]grpc.UnaryClientInterceptor{}, cm.userUnaryInterceptors...), retryInterceptor(cm))
+}
+
+// streamInterceptorChain is the streaming counterpart of unaryInterceptorChain.
+func streamInterceptorChain(cm *ConnectionManager) []grpc.StreamClientInterceptor {
+	return append(append([]grpc.StreamClientInterceptor{}, cm.userStreamInterceptors...), retryStreamInterceptor(cm))
+}
+
+// isRetryableStatus reports whether a gRPC error is a transient failure
+// worth retrying (shared by the unary and stream interceptors).
+func isRetryableStatus(err error) bool {
+	code := status.Code(err)
+	return code == codes.DeadlineExceeded || code == codes.Unavailable
+}
+
+// isConnectionFailureStatus reports whether err is Unavailable, grpc-go's
+// code for "the connection itself is the problem" as opposed to
+// DeadlineExceeded, which just as often means a healthy connection serving a
+// slow RPC. Only the former is reliable evidence to force a reconnect ahead
+// of minConnectionAge: forcing it on every DeadlineExceeded would churn
+// through endpoints under load instead of just retrying on the same one.
+func isConnectionFailureStatus(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
 func retryInterceptor(cm *ConnectionManager) grpc.UnaryClientInterceptor {
 
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		var err error
 
-		for attempt := 0; attempt < cm.maxRetries; attempt++ {
+		// activeConn is the connection attempts are invoked on; it starts as
+		// cc and is swapped for the result of reconnect so a retry after a
+		// failover actually lands on the new endpoint, not the stale one.
+		activeConn := cc
+
+		// Always invoke at least once, even if cm.maxRetries is 0: a
+		// valid-looking construction (New(addr, age, 0, false)) must still
+		// make the call rather than silently "succeeding" without it.
+		attempts := cm.maxRetries
+		if attempts < 1 {
+			attempts = 1
+		}
+
+		for attempt := 0; attempt < attempts; attempt++ {
 			select {
 			case <-ctx.Done():
 				return ctx.Err() // If the context is expired, return immediately
 
 			default:
-				var newConn *grpc.ClientConn = nil
-
-				if newConn == nil {
-					err = invoker(ctx, method, req, reply, cc, opts...)
-				} else {
-					err = invoker(ctx, method, req, reply, newConn, opts...)
-				}
+				err = invoker(ctx, method, req, reply, activeConn, opts...)
 
 				// If successful, return without retrying
 				if err == nil {
@@ -33,7 +73,7 @@ func retryInterceptor(cm *ConnectionManager) grpc.UnaryClientInterceptor {
 				}
 
 				// Check if the error is a timeout or transient failure
-				if status.Code(err) == codes.DeadlineExceeded || status.Code(err) == codes.Unavailable {
+				if isRetryableStatus(err) {
 
 					select {
 					case <-time.After(cm.backoffDuration(attempt)): // Wait before retrying
@@ -41,18 +81,184 @@ func retryInterceptor(cm *ConnectionManager) grpc.UnaryClientInterceptor {
 						return ctx.Err()
 					}
 
-					// Force reconnection if newtwork error
+					// Force reconnection if newtwork error. Unavailable is
+					// positive evidence the connection itself is unusable, so
+					// this bypasses minConnectionAge the same way the state
+					// monitor's forceReconnect does: waiting out the gate here
+					// would let the retries (and the caller's failover)
+					// exhaust against the same dead endpoint. DeadlineExceeded
+					// just as often means a healthy connection serving a slow
+					// RPC, so that case still goes through the gate.
 					GetLogger().Printf("Retrying request (attempt %d/%d) due to error: %v", attempt+1, cm.maxRetries, err)
-					newConn, _ = cm.TryReconnect()
+					if newConn, rerr := cm.reconnect(isConnectionFailureStatus(err)); rerr == nil && newConn != nil {
+						activeConn = newConn
+					}
 
 					continue
 				}
 
-				// If it's a non-retriable error, return immediately
-				break
+				// If it's a non-retriable error, return immediately. break
+				// here would only exit the select, not the for loop.
+				return err
 			}
 		}
 
 		return err
 	}
 }
+
+// retryStreamInterceptor mirrors retryInterceptor for streaming RPCs.
+// Retrying a stream is only safe before the first message has been
+// exchanged, so it retries NewStream itself and wraps the result in
+// retryClientStream, which surfaces later errors verbatim.
+func retryStreamInterceptor(cm *ConnectionManager) grpc.StreamClientInterceptor {
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		clientStream, err := newStreamWithRetry(ctx, cm, desc, cc, method, streamer, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return &retryClientStream{
+			ClientStream: clientStream,
+			cm:           cm,
+			ctx:          ctx,
+			desc:         desc,
+			cc:           cc,
+			method:       method,
+			streamer:     streamer,
+			opts:         opts,
+		}, nil
+	}
+}
+
+// newStreamWithRetry calls streamer to open the stream, retrying up to
+// cm.maxRetries times on Unavailable/DeadlineExceeded with the same backoff
+// and reconnect behavior as retryInterceptor. It always calls streamer at
+// least once, even if cm.maxRetries is 0, so a valid-looking construction
+// (New(addr, age, 0, false)) can't silently skip the call and hand back a
+// nil stream.
+func newStreamWithRetry(ctx context.Context, cm *ConnectionManager, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	var err error
+
+	attempts := cm.maxRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		var clientStream grpc.ClientStream
+		clientStream, err = streamer(ctx, desc, cc, method, opts...)
+		if err == nil {
+			return clientStream, nil
+		}
+
+		if !isRetryableStatus(err) {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(cm.backoffDuration(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// See retryInterceptor: only Unavailable is evidence cc itself is
+		// unusable, so only that bypasses minConnectionAge.
+		GetLogger().Printf("Retrying NewStream (attempt %d/%d) due to error: %v", attempt+1, cm.maxRetries, err)
+		if newConn, rerr := cm.reconnect(isConnectionFailureStatus(err)); rerr == nil && newConn != nil {
+			cc = newConn
+		}
+	}
+
+	return nil, err
+}
+
+// retryClientStream wraps a grpc.ClientStream so that a retryable failure on
+// the very first SendMsg/RecvMsg — which is where some transports actually
+// surface a dial failure, rather than from NewStream itself — can still
+// re-establish the stream. Once a message has been exchanged, started is
+// latched and every later error is returned unmodified: retrying after data
+// has crossed the wire would break at-most-once semantics for non-idempotent
+// streams.
+type retryClientStream struct {
+	grpc.ClientStream
+
+	cm       *ConnectionManager
+	ctx      context.Context
+	desc     *grpc.StreamDesc
+	cc       *grpc.ClientConn
+	method   string
+	streamer grpc.Streamer
+	opts     []grpc.CallOption
+
+	mu      sync.Mutex
+	started bool
+}
+
+// retryOnce re-establishes the underlying stream and swaps it in, but only
+// if no message has been exchanged yet. Returns false if a retry is not
+// (or no longer) safe.
+func (s *retryClientStream) retryOnce(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started || !isRetryableStatus(err) {
+		return false
+	}
+
+	// See retryInterceptor: only Unavailable is evidence s.cc itself is
+	// unusable, so only that bypasses minConnectionAge.
+	if newConn, rerr := s.cm.reconnect(isConnectionFailureStatus(err)); rerr == nil && newConn != nil {
+		s.cc = newConn
+	}
+
+	newStream, rerr := newStreamWithRetry(s.ctx, s.cm, s.desc, s.cc, s.method, s.streamer, s.opts...)
+	if rerr != nil {
+		return false
+	}
+
+	s.ClientStream = newStream
+	return true
+}
+
+// currentStream returns the wrapped grpc.ClientStream under mu, since
+// retryOnce swaps it out from a concurrent goroutine on a successful retry.
+func (s *retryClientStream) currentStream() grpc.ClientStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ClientStream
+}
+
+func (s *retryClientStream) SendMsg(m interface{}) error {
+	err := s.currentStream().SendMsg(m)
+	if err != nil && s.retryOnce(err) {
+		err = s.currentStream().SendMsg(m)
+	}
+
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+
+	return err
+}
+
+func (s *retryClientStream) RecvMsg(m interface{}) error {
+	err := s.currentStream().RecvMsg(m)
+	if err != nil && s.retryOnce(err) {
+		err = s.currentStream().RecvMsg(m)
+	}
+
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+
+	return err
+}
@@ -3,10 +3,11 @@ package glink
 import (
 	"log"
 	"sync"
+	"sync/atomic"
 )
 
 type Logger struct {
-	enabled bool
+	enabled atomic.Bool
 }
 
 var (
@@ -17,30 +18,30 @@ var (
 // GetLogger returns the singleton instance of Logger
 func GetLogger() *Logger {
 	once.Do(func() {
-		instance = &Logger{enabled: false} // Default: logging enabled
+		instance = &Logger{} // Default: logging disabled
 	})
 	return instance
 }
 
 // Enable turns logging on
 func (l *Logger) Enable() {
-	l.enabled = true
+	l.enabled.Store(true)
 }
 
 // Disable turns logging off
 func (l *Logger) Disable() {
-	l.enabled = false
+	l.enabled.Store(false)
 }
 
 // Println logs a message only if logging is enabled
 func (l *Logger) Println(v ...interface{}) {
-	if l.enabled {
+	if l.enabled.Load() {
 		log.Println(v...)
 	}
 }
 
 func (l *Logger) Printf(format string, v ...interface{}) {
-	if l.enabled {
+	if l.enabled.Load() {
 		log.Printf(format, v...)
 	}
 }
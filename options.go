@@ -0,0 +1,160 @@
+package glink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"time"
+)
+
+// Option configures a ConnectionManager at construction time. Options are
+// applied in the order they are passed to NewWithOptions, so later options
+// may refine settings established by earlier ones (e.g. WithServerName
+// after WithTLS).
+type Option func(*ConnectionManager)
+
+// WithTLS configures the ConnectionManager to dial using the supplied TLS
+// configuration instead of insecure credentials. The credentials are built
+// from this configuration once, in NewWithOptions, and reused on every
+// reconnect.
+func WithTLS(cfg *tls.Config) Option {
+	return func(cm *ConnectionManager) {
+		cm.tlsConfig = cfg
+	}
+}
+
+// WithClientCertificate configures mutual TLS: the client presents the
+// certificate/key pair loaded from certFile/keyFile, and caPool is used to
+// verify the server's certificate.
+//
+// If the certificate/key pair fails to load, NewWithOptions panics rather
+// than falling back to insecure credentials: silently downgrading a caller
+// who explicitly asked for mTLS to plaintext, because of e.g. a typo'd
+// path, is a far worse outcome than failing loudly at construction time.
+func WithClientCertificate(certFile, keyFile string, caPool *x509.CertPool) Option {
+	return func(cm *ConnectionManager) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			cm.certErr = fmt.Errorf("failed to load client certificate (%s, %s): %w", certFile, keyFile, err)
+			return
+		}
+
+		if cm.tlsConfig == nil {
+			cm.tlsConfig = &tls.Config{}
+		}
+
+		cm.tlsConfig.Certificates = []tls.Certificate{cert}
+		cm.tlsConfig.RootCAs = caPool
+	}
+}
+
+// WithServerName overrides the server name used for TLS verification
+// (equivalent to tls.Config.ServerName). It is typically used when the
+// service address is an IP or load-balancer endpoint that does not match
+// the certificate's subject.
+func WithServerName(name string) Option {
+	return func(cm *ConnectionManager) {
+		if cm.tlsConfig == nil {
+			cm.tlsConfig = &tls.Config{}
+		}
+
+		cm.tlsConfig.ServerName = name
+	}
+}
+
+// WithEndpoints replaces the ConnectionManager's endpoint list outright,
+// including the primary address passed to New — list it again in endpoints
+// if it should still be dialed. The selection strategy defaults to
+// RoundRobin; use WithEndpointSelection to change it. Equivalent to calling
+// SetEndpoints after construction.
+func WithEndpoints(endpoints ...string) Option {
+	return func(cm *ConnectionManager) {
+		cm.endpoints = endpoints
+		cm.endpointIndex = 0
+	}
+}
+
+// WithEndpointSelection sets the strategy used to pick the next endpoint on
+// reconnect (RoundRobin, RandomEndpoint, or StickyEndpoint).
+func WithEndpointSelection(strategy EndpointSelection) Option {
+	return func(cm *ConnectionManager) {
+		cm.endpointSelection = strategy
+	}
+}
+
+// WithKeepalive configures gRPC keepalive pings on the dialed connection,
+// so long-idle connections through a NAT or load balancer are detected and
+// torn down instead of appearing healthy until maxConnectionAge elapses.
+func WithKeepalive(params keepalive.ClientParameters) Option {
+	return func(cm *ConnectionManager) {
+		cm.keepaliveParams = &params
+	}
+}
+
+// WithConnectParams configures the backoff and minimum connect timeout
+// grpc-go itself applies while establishing the underlying transport
+// (distinct from the retry backoff used between failed RPCs).
+func WithConnectParams(params grpc.ConnectParams) Option {
+	return func(cm *ConnectionManager) {
+		cm.connectParams = &params
+	}
+}
+
+// WithBackoff overrides the base delay, cap, and multiplier used by
+// backoffDuration's full-jitter retry backoff. Defaults to base=100ms,
+// cap=30s, multiplier=1.6, matching gRPC's own internal/backoff defaults.
+func WithBackoff(base, cap time.Duration, multiplier float64) Option {
+	return func(cm *ConnectionManager) {
+		cm.backoffBase = base
+		cm.backoffCap = cap
+		cm.backoffMultiplier = multiplier
+	}
+}
+
+// WithOnStateChange registers a callback invoked by the background state
+// monitor on every gRPC connectivity state transition of the active
+// connection (e.g. Ready -> TransientFailure).
+func WithOnStateChange(fn func(old, new connectivity.State)) Option {
+	return func(cm *ConnectionManager) {
+		cm.onStateChange = fn
+	}
+}
+
+// WithUnaryInterceptors adds user unary interceptors (tracing, metrics,
+// auth, ...) to the dial chain. They run outermost of the retry
+// interceptor, which is always kept innermost, so they observe exactly one
+// call for the attempt the retries settle on, not once per attempt.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(cm *ConnectionManager) {
+		cm.userUnaryInterceptors = append(cm.userUnaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors is the streaming counterpart of WithUnaryInterceptors.
+func WithStreamInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(cm *ConnectionManager) {
+		cm.userStreamInterceptors = append(cm.userStreamInterceptors, interceptors...)
+	}
+}
+
+// WithPerRPCCredentials attaches per-RPC credentials (e.g. a bearer/JWT
+// token) to every call made through the ConnectionManager.
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) Option {
+	return func(cm *ConnectionManager) {
+		cm.perRPCCreds = creds
+	}
+}
+
+// WithInsecure forces plaintext transport credentials, overriding any TLS
+// configuration supplied by earlier options. It is mainly useful when
+// options are composed dynamically and insecure mode needs to win.
+func WithInsecure() Option {
+	return func(cm *ConnectionManager) {
+		cm.tlsConfig = nil
+		cm.forceInsecure = true
+	}
+}
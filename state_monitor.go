@@ -0,0 +1,56 @@
+package glink
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// statePollInterval is how often the state monitor checks back for a
+// connection to watch when none has been established yet.
+const statePollInterval = 200 * time.Millisecond
+
+// monitorConnectionState watches the active connection's gRPC connectivity
+// state via WaitForStateChange and triggers a reconnect as soon as it enters
+// TransientFailure or Shutdown, rather than waiting for maxConnectionAge to
+// elapse. It runs until ctx is cancelled, which Close() does.
+//
+// A dropped transport (server crash, closed connection) takes the
+// subchannel Ready -> Idle, not Ready -> TransientFailure: grpc-go only
+// redials out of Idle on the next RPC or an explicit Connect() call, so
+// without the nudge below the connection would sit in Idle indefinitely
+// instead of ever reaching a state this monitor reacts to.
+func (cm *ConnectionManager) monitorConnectionState(ctx context.Context) {
+	for {
+		conn := cm.connection1.Connection()
+		if conn == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(statePollInterval):
+				continue
+			}
+		}
+
+		current := conn.GetState()
+		if !conn.WaitForStateChange(ctx, current) {
+			return // ctx was cancelled
+		}
+
+		next := conn.GetState()
+		if cm.onStateChange != nil {
+			cm.onStateChange(current, next)
+		}
+
+		if next == connectivity.Idle {
+			GetLogger().Printf("Connection state changed %s -> %s, forcing a redial attempt", current, next)
+			conn.Connect()
+		}
+
+		if next == connectivity.TransientFailure || next == connectivity.Shutdown {
+			GetLogger().Printf("Connection state changed %s -> %s, triggering reconnect", current, next)
+			_, _ = cm.forceReconnect()
+		}
+	}
+}